@@ -1,9 +1,9 @@
 package dag
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"sync"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -17,6 +17,12 @@ type AcyclicGraph struct {
 // WalkFunc is the callback used for walking the graph.
 type WalkFunc func(Vertex) error
 
+// DiagWalkFunc is the callback used for walking the graph when a vertex
+// needs to report warnings alongside (or instead of) errors. Use it with
+// WalkDiag in place of Walk when callers need access to the full set of
+// diagnostics rather than just the aggregated error.
+type DiagWalkFunc func(Vertex) Diagnostics
+
 // Root returns the root of the DAG, or an error.
 //
 // Complexity: O(V)
@@ -40,6 +46,30 @@ func (g *AcyclicGraph) Root() (Vertex, error) {
 	return roots[0], nil
 }
 
+// Cycles returns every cycle in the graph: each strongly connected
+// component of size 2 or more (found with an iterative implementation
+// of Tarjan's algorithm, so it won't blow the stack on large graphs),
+// plus every vertex with a self-edge as a cycle of its own.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph) Cycles() [][]Vertex {
+	var cycles [][]Vertex
+
+	for _, scc := range tarjanSCC(&g.Graph) {
+		if len(scc) > 1 {
+			cycles = append(cycles, scc)
+		}
+	}
+
+	for _, e := range g.Edges() {
+		if e.Source() == e.Target() {
+			cycles = append(cycles, []Vertex{e.Source()})
+		}
+	}
+
+	return cycles
+}
+
 // TransitiveReduction performs the transitive reduction of graph g in place.
 // The transitive reduction of a graph is a graph with as few edges as
 // possible with the same reachability as the original graph. This means
@@ -48,12 +78,17 @@ func (g *AcyclicGraph) Root() (Vertex, error) {
 // same graph with only a single edge between A and B, and a single edge
 // between B and C.
 //
-// The graph must be valid for this operation to behave properly. If
-// Validate() returns an error, the behavior is undefined and the results
-// will likely be unexpected.
+// The graph must be valid (as reported by Validate) for this operation
+// to behave properly; TransitiveReduction returns an error, leaving g
+// unmodified, if it finds a cycle rather than proceeding with undefined
+// results.
 //
 // Complexity: O(V(V+E)), or asymptotically O(VE)
-func (g *AcyclicGraph) TransitiveReduction() {
+func (g *AcyclicGraph) TransitiveReduction() error {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		return fmt.Errorf("cannot perform transitive reduction: graph has %d cycle(s)", len(cycles))
+	}
+
 	// For each vertex u in graph g, do a DFS starting from each vertex
 	// v such that the edge (u,v) exists (v is a direct descendant of u).
 	//
@@ -66,7 +101,7 @@ func (g *AcyclicGraph) TransitiveReduction() {
 			vs[i] = vRaw.(Vertex)
 		}
 
-		g.depthFirstWalk(vs, func(v Vertex) error {
+		g.DepthFirstWalk(vs, func(v Vertex, _ int) error {
 			shared := uTargets.Intersection(g.DownEdges(v))
 			for _, raw := range shared.List() {
 				vPrime := raw.(Vertex)
@@ -76,6 +111,8 @@ func (g *AcyclicGraph) TransitiveReduction() {
 			return nil
 		})
 	}
+
+	return nil
 }
 
 // Validate validates the DAG. A DAG is valid if it has a single root
@@ -85,151 +122,113 @@ func (g *AcyclicGraph) Validate() error {
 		return err
 	}
 
-	// Look for cycles of more than 1 component
 	var err error
-	var cycles [][]Vertex
-	for _, cycle := range StronglyConnected(&g.Graph) {
-		if len(cycle) > 1 {
-			cycles = append(cycles, cycle)
-		}
-	}
-	if len(cycles) > 0 {
-		for _, cycle := range cycles {
-			cycleStr := make([]string, len(cycle))
-			for j, vertex := range cycle {
-				cycleStr[j] = VertexName(vertex)
-			}
-
+	for _, cycle := range g.Cycles() {
+		if len(cycle) == 1 {
 			err = multierror.Append(err, fmt.Errorf(
-				"Cycle: %s", strings.Join(cycleStr, ", ")))
+				"Self reference: %s", VertexName(cycle[0])))
+			continue
 		}
-	}
 
-	// Look for cycles to self
-	for _, e := range g.Edges() {
-		if e.Source() == e.Target() {
-			err = multierror.Append(err, fmt.Errorf(
-				"Self reference: %s", VertexName(e.Source())))
+		cycleStr := make([]string, len(cycle))
+		for j, vertex := range cycle {
+			cycleStr[j] = VertexName(vertex)
 		}
+
+		err = multierror.Append(err, fmt.Errorf(
+			"Cycle: %s", strings.Join(cycleStr, ", ")))
 	}
 
 	return err
 }
 
+// Walker returns a new Walker configured to call cb as each vertex is
+// visited. Unlike Walk, the returned Walker's vertices and edges are not
+// yet populated: callers drive it by calling Update one or more times
+// (even while a previous Update's callbacks are still executing) and
+// then Wait for completion.
+//
+// This is the building block Walk uses, and it is what callers that need
+// to mutate the graph while a walk is in flight (for example, a planner
+// that discovers new vertices as it executes) should use directly.
+func (g *AcyclicGraph) Walker(cb WalkFunc) *Walker {
+	return &Walker{Callback: cb}
+}
+
 // Walk walks the graph, calling your callback as each node is visited.
 // This will walk nodes in parallel if it can. Because the walk is done
 // in parallel, the error returned will be a multierror.
 func (g *AcyclicGraph) Walk(cb WalkFunc) error {
-	// Cache the vertices since we use it multiple times
-	vertices := g.Vertices()
-
-	// Build the waitgroup that signals when we're done
-	var wg sync.WaitGroup
-	wg.Add(len(vertices))
-	doneCh := make(chan struct{})
-	go func() {
-		defer close(doneCh)
-		wg.Wait()
-	}()
-
-	// The map of channels to watch to wait for vertices to finish
-	vertMap := make(map[Vertex]chan struct{})
-	for _, v := range vertices {
-		vertMap[v] = make(chan struct{})
-	}
-
-	// The map of whether a vertex errored or not during the walk
-	var errLock sync.Mutex
-	var errs error
-	errMap := make(map[Vertex]bool)
-	for _, v := range vertices {
-		// Build our list of dependencies and the list of channels to
-		// wait on until we start executing for this vertex.
-		depsRaw := g.DownEdges(v).List()
-		deps := make([]Vertex, len(depsRaw))
-		depChs := make([]<-chan struct{}, len(deps))
-		for i, raw := range depsRaw {
-			deps[i] = raw.(Vertex)
-			depChs[i] = vertMap[deps[i]]
-		}
+	w := g.Walker(cb)
 
-		// Get our channel so that we can close it when we're done
-		ourCh := vertMap[v]
+	vertices := new(Set)
+	for _, v := range g.Vertices() {
+		vertices.Add(v)
+	}
 
-		// Start the goroutine to wait for our dependencies
-		readyCh := make(chan bool)
-		go func(deps []Vertex, chs []<-chan struct{}, readyCh chan<- bool) {
-			// First wait for all the dependencies
-			for _, ch := range chs {
-				<-ch
-			}
+	edges := new(Set)
+	for _, e := range g.Edges() {
+		edges.Add(e)
+	}
 
-			// Then, check the map to see if any of our dependencies failed
-			errLock.Lock()
-			defer errLock.Unlock()
-			for _, dep := range deps {
-				if errMap[dep] {
-					readyCh <- false
-					return
-				}
-			}
+	w.Update(vertices, edges)
+	return w.Wait()
+}
 
-			readyCh <- true
-		}(deps, depChs, readyCh)
+// WalkOpts configures a call to WalkCtx.
+type WalkOpts struct {
+	// MaxParallel limits how many vertex callbacks may be executing
+	// concurrently. Zero (the default) means unbounded, same as Walk.
+	MaxParallel int
+}
 
-		// Start the goroutine that executes
-		go func(v Vertex, doneCh chan<- struct{}, readyCh <-chan bool) {
-			defer close(doneCh)
-			defer wg.Done()
+// WalkCtx walks the graph like Walk, but accepts a context.Context and a
+// WalkOpts. Unlike Walk, which spawns one goroutine per vertex and
+// cannot be interrupted, WalkCtx caps concurrent callbacks at
+// opts.MaxParallel (useful when a callback triggers slow external work,
+// such as a cloud API call) and honors ctx.Done(): once the context is
+// done, in-flight callbacks run to completion, but every vertex that
+// hasn't started yet is marked as skipped and treated like an errored
+// dependency for downstream propagation.
+func (g *AcyclicGraph) WalkCtx(ctx context.Context, opts WalkOpts, cb WalkFunc) error {
+	w := &Walker{
+		Callback:    cb,
+		Context:     ctx,
+		MaxParallel: opts.MaxParallel,
+	}
 
-			var err error
-			if ready := <-readyCh; ready {
-				err = cb(v)
-			}
+	vertices := new(Set)
+	for _, v := range g.Vertices() {
+		vertices.Add(v)
+	}
 
-			errLock.Lock()
-			defer errLock.Unlock()
-			if err != nil {
-				errMap[v] = true
-				errs = multierror.Append(errs, err)
-			}
-		}(v, ourCh, readyCh)
+	edges := new(Set)
+	for _, e := range g.Edges() {
+		edges.Add(e)
 	}
 
-	<-doneCh
-	return errs
+	w.Update(vertices, edges)
+	return w.Wait()
 }
 
-// depthFirstWalk does a depth-first walk of the graph starting from
-// the vertices in start. This is not exported now but it would make sense
-// to export this publicly at some point.
-func (g *AcyclicGraph) depthFirstWalk(start []Vertex, cb WalkFunc) error {
-	seen := make(map[Vertex]struct{})
-	frontier := make([]Vertex, len(start))
-	copy(frontier, start)
-	for len(frontier) > 0 {
-		// Pop the current vertex
-		n := len(frontier)
-		current := frontier[n-1]
-		frontier = frontier[:n-1]
-
-		// Check if we've seen this already and return...
-		if _, ok := seen[current]; ok {
-			continue
-		}
-		seen[current] = struct{}{}
+// WalkDiag walks the graph like Walk, but allows each vertex's callback
+// to return Diagnostics carrying both warnings and errors instead of a
+// single error. Diagnostics are aggregated from every vertex rather than
+// short-circuiting on the first error, and only error-severity
+// diagnostics cause downstream vertices to be skipped.
+func (g *AcyclicGraph) WalkDiag(cb DiagWalkFunc) Diagnostics {
+	w := &Walker{diagCallback: cb}
 
-		// Visit the current node
-		if err := cb(current); err != nil {
-			return err
-		}
+	vertices := new(Set)
+	for _, v := range g.Vertices() {
+		vertices.Add(v)
+	}
 
-		// Visit targets of this in reverse order.
-		targets := g.DownEdges(current).List()
-		for i := len(targets) - 1; i >= 0; i-- {
-			frontier = append(frontier, targets[i].(Vertex))
-		}
+	edges := new(Set)
+	for _, e := range g.Edges() {
+		edges.Add(e)
 	}
 
-	return nil
+	w.Update(vertices, edges)
+	return w.Diags()
 }
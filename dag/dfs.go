@@ -0,0 +1,135 @@
+package dag
+
+import "sort"
+
+// DepthWalkFunc is the callback used for DepthFirstWalk and its
+// variants. depth is the number of edges from the walk's start set to v
+// along the path by which v was first reached. In the breadth-first
+// variants this is the shortest such distance; in the depth-first
+// variants it can be longer.
+type DepthWalkFunc func(v Vertex, depth int) error
+
+// DepthFirstWalk does a depth-first walk of the graph starting from the
+// vertices in start, following DownEdges and reporting each vertex's
+// depth from start to cb. This is useful for operations like computing
+// module nesting depth or finding everything reachable from a target.
+//
+// Neighbors are visited in VertexName order so that, for a given graph
+// and start set, the walk order is stable across runs.
+func (g *AcyclicGraph) DepthFirstWalk(start []Vertex, cb DepthWalkFunc) error {
+	return depthFirstWalk(start, g.DownEdges, cb)
+}
+
+// ReverseDepthFirstWalk does a depth-first walk of the graph starting
+// from the vertices in start, following UpEdges instead of DownEdges.
+// This is useful for operations like destroying in reverse dependency
+// order.
+func (g *AcyclicGraph) ReverseDepthFirstWalk(start []Vertex, cb DepthWalkFunc) error {
+	return depthFirstWalk(start, g.UpEdges, cb)
+}
+
+// BreadthFirstWalk does a breadth-first walk of the graph starting from
+// the vertices in start, following DownEdges.
+func (g *AcyclicGraph) BreadthFirstWalk(start []Vertex, cb DepthWalkFunc) error {
+	return breadthFirstWalk(start, g.DownEdges, cb)
+}
+
+// ReverseBreadthFirstWalk does a breadth-first walk of the graph
+// starting from the vertices in start, following UpEdges instead of
+// DownEdges.
+func (g *AcyclicGraph) ReverseBreadthFirstWalk(start []Vertex, cb DepthWalkFunc) error {
+	return breadthFirstWalk(start, g.UpEdges, cb)
+}
+
+// depthFirstWalk visits start and everything reachable from it via
+// neighbors, in depth-first order, stopping at the first error.
+func depthFirstWalk(start []Vertex, neighbors func(Vertex) *Set, cb DepthWalkFunc) error {
+	seen := make(map[Vertex]struct{})
+
+	type frame struct {
+		v     Vertex
+		depth int
+	}
+
+	frontier := make([]frame, len(start))
+	for i, v := range start {
+		frontier[i] = frame{v: v}
+	}
+
+	for len(frontier) > 0 {
+		// Pop the current vertex
+		n := len(frontier)
+		current := frontier[n-1]
+		frontier = frontier[:n-1]
+
+		if _, ok := seen[current.v]; ok {
+			continue
+		}
+		seen[current.v] = struct{}{}
+
+		if err := cb(current.v, current.depth); err != nil {
+			return err
+		}
+
+		// Push targets in reverse sorted order, so they come back off
+		// the stack (and so get visited) in sorted order.
+		targets := sortedNeighbors(neighbors(current.v))
+		for i := len(targets) - 1; i >= 0; i-- {
+			frontier = append(frontier, frame{targets[i], current.depth + 1})
+		}
+	}
+
+	return nil
+}
+
+// breadthFirstWalk visits start and everything reachable from it via
+// neighbors, in breadth-first order, stopping at the first error.
+func breadthFirstWalk(start []Vertex, neighbors func(Vertex) *Set, cb DepthWalkFunc) error {
+	seen := make(map[Vertex]struct{})
+
+	type item struct {
+		v     Vertex
+		depth int
+	}
+
+	queue := make([]item, len(start))
+	for i, v := range start {
+		queue[i] = item{v: v}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if _, ok := seen[current.v]; ok {
+			continue
+		}
+		seen[current.v] = struct{}{}
+
+		if err := cb(current.v, current.depth); err != nil {
+			return err
+		}
+
+		for _, v := range sortedNeighbors(neighbors(current.v)) {
+			queue = append(queue, item{v, current.depth + 1})
+		}
+	}
+
+	return nil
+}
+
+// sortedNeighbors returns s's members as a []Vertex sorted by
+// VertexName, so that callers get a deterministic traversal order.
+func sortedNeighbors(s *Set) []Vertex {
+	raw := s.List()
+	vs := make([]Vertex, len(raw))
+	for i, v := range raw {
+		vs[i] = v.(Vertex)
+	}
+
+	sort.Slice(vs, func(i, j int) bool {
+		return VertexName(vs[i]) < VertexName(vs[j])
+	})
+
+	return vs
+}
@@ -0,0 +1,103 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAcyclicGraph_DepthFirstWalk(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(1, 3))
+
+	var visited []Vertex
+	var depths []int
+	err := g.DepthFirstWalk([]Vertex{1}, func(v Vertex, depth int) error {
+		visited = append(visited, v)
+		depths = append(depths, depth)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(visited, []Vertex{1, 2, 3}) {
+		t.Fatalf("expected [1 2 3] in VertexName order, got %#v", visited)
+	}
+	if !reflect.DeepEqual(depths, []int{0, 1, 1}) {
+		t.Fatalf("expected depths [0 1 1], got %#v", depths)
+	}
+}
+
+func TestAcyclicGraph_ReverseDepthFirstWalk(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+
+	var visited []Vertex
+	err := g.ReverseDepthFirstWalk([]Vertex{2}, func(v Vertex, depth int) error {
+		visited = append(visited, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(visited, []Vertex{2, 1}) {
+		t.Fatalf("expected [2 1], got %#v", visited)
+	}
+}
+
+func TestAcyclicGraph_BreadthFirstWalk(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Add(4)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(1, 3))
+	g.Connect(BasicEdge(2, 4))
+	g.Connect(BasicEdge(3, 4))
+
+	var visited []Vertex
+	err := g.BreadthFirstWalk([]Vertex{1}, func(v Vertex, depth int) error {
+		visited = append(visited, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(visited, []Vertex{1, 2, 3, 4}) {
+		t.Fatalf("expected breadth-first order [1 2 3 4], got %#v", visited)
+	}
+}
+
+func TestAcyclicGraph_ReverseBreadthFirstWalk(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Add(4)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(1, 3))
+	g.Connect(BasicEdge(2, 4))
+	g.Connect(BasicEdge(3, 4))
+
+	var visited []Vertex
+	err := g.ReverseBreadthFirstWalk([]Vertex{4}, func(v Vertex, depth int) error {
+		visited = append(visited, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(visited, []Vertex{4, 2, 3, 1}) {
+		t.Fatalf("expected reverse breadth-first order [4 2 3 1], got %#v", visited)
+	}
+}
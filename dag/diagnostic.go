@@ -0,0 +1,104 @@
+package dag
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Severity describes how much a Diagnostic matters: whether it prevents
+// the graph from being considered successfully walked (Error) or is
+// purely informational (Warning).
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+// Diagnostic is a single warning or error surfaced by a DiagWalkFunc.
+// Unlike a plain error, a Diagnostic's severity determines whether it
+// causes downstream vertices to be skipped.
+type Diagnostic interface {
+	Severity() Severity
+	Summary() string
+	Detail() string
+}
+
+// Diagnostics is a list of zero or more Diagnostic values accumulated
+// over the course of a walk.
+type Diagnostics []Diagnostic
+
+// HasErrors returns true if the set contains at least one diagnostic of
+// Error severity.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity() == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Append adds the given diagnostics to the set, ignoring any nil values,
+// and returns the resulting set.
+func (d Diagnostics) Append(new ...Diagnostic) Diagnostics {
+	for _, diag := range new {
+		if diag == nil {
+			continue
+		}
+
+		d = append(d, diag)
+	}
+
+	return d
+}
+
+// Err returns the error-severity diagnostics in the set as a single
+// multierror, or nil if there are none. This is used to let callers that
+// only care about errors interoperate with APIs expecting a plain error.
+func (d Diagnostics) Err() error {
+	if !d.HasErrors() {
+		return nil
+	}
+
+	var result error
+	for _, diag := range d {
+		if diag.Severity() != Error {
+			continue
+		}
+
+		result = multierror.Append(result, fmt.Errorf("%s: %s", diag.Summary(), diag.Detail()))
+	}
+
+	return result
+}
+
+// diagnostic is the Diagnostic implementation used to wrap a plain error
+// returned from a WalkFunc so it can flow through the same Diagnostics
+// plumbing as a native DiagWalkFunc.
+type diagnostic struct {
+	severity Severity
+	summary  string
+	detail   string
+}
+
+func (d *diagnostic) Severity() Severity { return d.severity }
+func (d *diagnostic) Summary() string    { return d.summary }
+func (d *diagnostic) Detail() string     { return d.detail }
+
+// diagnosticsForError wraps a plain error, as returned by a WalkFunc for
+// the given vertex, in a single error-severity Diagnostics value. It
+// returns nil if err is nil.
+func diagnosticsForError(v Vertex, err error) Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	return Diagnostics{&diagnostic{
+		severity: Error,
+		summary:  VertexName(v),
+		detail:   err.Error(),
+	}}
+}
@@ -0,0 +1,58 @@
+package dag
+
+import "testing"
+
+type testDiag struct {
+	severity Severity
+	summary  string
+	detail   string
+}
+
+func (d *testDiag) Severity() Severity { return d.severity }
+func (d *testDiag) Summary() string    { return d.summary }
+func (d *testDiag) Detail() string     { return d.detail }
+
+func TestDiagnostics_HasErrors(t *testing.T) {
+	var diags Diagnostics
+	if diags.HasErrors() {
+		t.Fatal("empty Diagnostics should not have errors")
+	}
+
+	diags = diags.Append(&testDiag{severity: Warning, summary: "watch out"})
+	if diags.HasErrors() {
+		t.Fatal("a warning alone should not count as an error")
+	}
+
+	diags = diags.Append(&testDiag{severity: Error, summary: "boom"})
+	if !diags.HasErrors() {
+		t.Fatal("expected HasErrors to be true once an error is appended")
+	}
+}
+
+func TestAcyclicGraph_WalkDiag(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Connect(BasicEdge(1, 2))
+
+	diags := g.WalkDiag(func(v Vertex) Diagnostics {
+		switch v {
+		case 1:
+			return Diagnostics{&testDiag{severity: Error, summary: "1 failed"}}
+		case 2:
+			t.Fatal("vertex 2 depends on failed vertex 1 and should have been skipped")
+		case 3:
+			return Diagnostics{&testDiag{severity: Warning, summary: "3 is fine, but noisy"}}
+		}
+
+		return nil
+	})
+
+	if !diags.HasErrors() {
+		t.Fatal("expected at least one error-severity diagnostic")
+	}
+	if len(diags) != 2 {
+		t.Fatalf("expected the error from 1 and the warning from 3, got %d: %#v", len(diags), diags)
+	}
+}
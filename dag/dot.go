@@ -0,0 +1,193 @@
+package dag
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// DotOpts contains options for controlling the dot output produced by
+// Dot.
+type DotOpts struct {
+	// MaxDepth limits how many edges are traversed outward from the
+	// graph's root vertices before rendering stops. Zero (the default)
+	// means no limit.
+	MaxDepth int
+
+	// Verbose includes a comment listing each vertex's dependencies,
+	// which is normally omitted to keep small graphs easy to read.
+	Verbose bool
+
+	// DrawCycles finds the graph's strongly connected components (with
+	// tarjanSCC) and renders the edges that form a cycle in red, so
+	// problem areas are easy to spot visually.
+	DrawCycles bool
+}
+
+// Subgrapher is the interface implemented by a Vertex that wants to be
+// rendered nested inside its own `subgraph cluster_...` block rather
+// than at the top level, for example to visually group the vertices
+// that belong to the same module.
+type Subgrapher interface {
+	Subgraph() (name string, g *Graph)
+}
+
+// Dot returns a GraphViz dot-formatted rendering of g, suitable for
+// visualization and debugging with tools like `dot -Tpng`.
+func Dot(g *Graph, opts *DotOpts) []byte {
+	if opts == nil {
+		opts = &DotOpts{}
+	}
+
+	var include map[Vertex]bool
+	if opts.MaxDepth > 0 {
+		include = dotReachable(g, opts.MaxDepth)
+	}
+
+	var cycleEdges map[Edge]bool
+	if opts.DrawCycles {
+		cycleEdges = dotCycleEdges(g)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph {\n")
+	writeDotGraph(&buf, g, opts, include, cycleEdges, "\t")
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+func writeDotGraph(
+	buf *bytes.Buffer,
+	g *Graph,
+	opts *DotOpts,
+	include map[Vertex]bool,
+	cycleEdges map[Edge]bool,
+	indent string) {
+
+	vertices := g.Vertices()
+	sort.Slice(vertices, func(i, j int) bool {
+		return VertexName(vertices[i]) < VertexName(vertices[j])
+	})
+
+	for _, v := range vertices {
+		if include != nil && !include[v] {
+			continue
+		}
+
+		if sg, ok := v.(Subgrapher); ok {
+			name, nested := sg.Subgraph()
+			fmt.Fprintf(buf, "%ssubgraph %q {\n", indent, "cluster_"+name)
+			fmt.Fprintf(buf, "%s\tlabel = %q;\n", indent, name)
+			writeDotGraph(buf, nested, opts, nil, cycleEdges, indent+"\t")
+			fmt.Fprintf(buf, "%s}\n", indent)
+			continue
+		}
+
+		fmt.Fprintf(buf, "%s%q;\n", indent, VertexName(v))
+
+		if opts.Verbose {
+			deps := g.DownEdges(v).List()
+			names := make([]string, len(deps))
+			for i, raw := range deps {
+				names[i] = VertexName(raw.(Vertex))
+			}
+			sort.Strings(names)
+			fmt.Fprintf(buf, "%s// %s depends on: %v\n", indent, VertexName(v), names)
+		}
+	}
+
+	edges := g.Edges()
+	sort.Slice(edges, func(i, j int) bool {
+		if s1, s2 := VertexName(edges[i].Source()), VertexName(edges[j].Source()); s1 != s2 {
+			return s1 < s2
+		}
+		return VertexName(edges[i].Target()) < VertexName(edges[j].Target())
+	})
+
+	for _, e := range edges {
+		if include != nil && (!include[e.Source()] || !include[e.Target()]) {
+			continue
+		}
+
+		if cycleEdges[e] {
+			fmt.Fprintf(buf, "%s%q -> %q [color = \"red\"];\n", indent, VertexName(e.Source()), VertexName(e.Target()))
+			continue
+		}
+
+		fmt.Fprintf(buf, "%s%q -> %q;\n", indent, VertexName(e.Source()), VertexName(e.Target()))
+	}
+}
+
+// dotReachable returns the set of vertices reachable from g's roots
+// within maxDepth hops, used to implement DotOpts.MaxDepth.
+func dotReachable(g *Graph, maxDepth int) map[Vertex]bool {
+	include := make(map[Vertex]bool)
+
+	type queued struct {
+		v     Vertex
+		depth int
+	}
+
+	var frontier []queued
+	for _, v := range g.Vertices() {
+		if g.UpEdges(v).Len() == 0 {
+			frontier = append(frontier, queued{v, 0})
+		}
+	}
+
+	for len(frontier) > 0 {
+		cur := frontier[0]
+		frontier = frontier[1:]
+
+		if include[cur.v] {
+			continue
+		}
+		include[cur.v] = true
+
+		if cur.depth >= maxDepth {
+			continue
+		}
+
+		for _, raw := range g.DownEdges(cur.v).List() {
+			frontier = append(frontier, queued{raw.(Vertex), cur.depth + 1})
+		}
+	}
+
+	return include
+}
+
+// dotCycleEdges returns the set of edges that connect two vertices
+// belonging to the same strongly connected component, plus every
+// self-loop edge, for highlighting with DotOpts.DrawCycles. It uses the
+// iterative tarjanSCC rather than the older recursive StronglyConnected
+// so that DrawCycles doesn't reintroduce the recursion blowup on large
+// graphs that Cycles was written to avoid.
+func dotCycleEdges(g *Graph) map[Edge]bool {
+	cycleEdges := make(map[Edge]bool)
+
+	for _, cycle := range tarjanSCC(g) {
+		if len(cycle) < 2 {
+			continue
+		}
+
+		in := make(map[Vertex]bool, len(cycle))
+		for _, v := range cycle {
+			in[v] = true
+		}
+
+		for _, e := range g.Edges() {
+			if in[e.Source()] && in[e.Target()] {
+				cycleEdges[e] = true
+			}
+		}
+	}
+
+	for _, e := range g.Edges() {
+		if e.Source() == e.Target() {
+			cycleEdges[e] = true
+		}
+	}
+
+	return cycleEdges
+}
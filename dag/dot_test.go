@@ -0,0 +1,94 @@
+package dag
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDot(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+
+	actual := string(Dot(&g.Graph, nil))
+
+	if !strings.Contains(actual, `"1" -> "2"`) {
+		t.Fatalf("expected an edge from 1 to 2, got:\n%s", actual)
+	}
+}
+
+func TestDot_maxDepth(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(2, 3))
+
+	actual := string(Dot(&g.Graph, &DotOpts{MaxDepth: 1}))
+
+	if !strings.Contains(actual, `"1"`) || !strings.Contains(actual, `"2"`) {
+		t.Fatalf("expected vertices within depth 1 of the root, got:\n%s", actual)
+	}
+	if strings.Contains(actual, `"3"`) {
+		t.Fatalf("expected vertex 3 (depth 2) to be excluded by MaxDepth, got:\n%s", actual)
+	}
+}
+
+func TestDot_drawCycles(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(2, 1))
+
+	actual := string(Dot(&g.Graph, &DotOpts{DrawCycles: true}))
+
+	if !strings.Contains(actual, `color = "red"`) {
+		t.Fatalf("expected cycle edges to be highlighted in red, got:\n%s", actual)
+	}
+}
+
+type dotTestSubgrapher struct {
+	name string
+}
+
+func (v *dotTestSubgrapher) String() string { return v.name }
+
+func (v *dotTestSubgrapher) Subgraph() (string, *Graph) {
+	var g Graph
+	g.Add("inner")
+	return v.name, &g
+}
+
+func TestDot_subgrapher(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(&dotTestSubgrapher{name: "mod.child"})
+
+	actual := string(Dot(&g.Graph, nil))
+
+	if !strings.Contains(actual, `subgraph "cluster_mod.child"`) {
+		t.Fatalf("expected a nested cluster subgraph, got:\n%s", actual)
+	}
+	if !strings.Contains(actual, `"inner"`) {
+		t.Fatalf("expected the nested graph's vertex to be rendered, got:\n%s", actual)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+
+	out, err := MarshalJSON(&g.Graph)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := string(out)
+	if !strings.Contains(actual, `"vertices"`) || !strings.Contains(actual, `"source":"1"`) {
+		t.Fatalf("unexpected output: %s", actual)
+	}
+}
@@ -0,0 +1,47 @@
+package dag
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// marshalGraph is the JSON shape produced by MarshalJSON: a flat list of
+// vertex names and a flat list of edges, for consumers that want to
+// process the graph programmatically rather than rendering it with
+// GraphViz.
+type marshalGraph struct {
+	Vertices []string      `json:"vertices"`
+	Edges    []marshalEdge `json:"edges"`
+}
+
+type marshalEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// MarshalJSON renders g as JSON in the form
+// {"vertices": [...], "edges": [{"source": ..., "target": ...}, ...]},
+// with both lists sorted for stable output.
+func MarshalJSON(g *Graph) ([]byte, error) {
+	var mg marshalGraph
+
+	for _, v := range g.Vertices() {
+		mg.Vertices = append(mg.Vertices, VertexName(v))
+	}
+	sort.Strings(mg.Vertices)
+
+	for _, e := range g.Edges() {
+		mg.Edges = append(mg.Edges, marshalEdge{
+			Source: VertexName(e.Source()),
+			Target: VertexName(e.Target()),
+		})
+	}
+	sort.Slice(mg.Edges, func(i, j int) bool {
+		if mg.Edges[i].Source != mg.Edges[j].Source {
+			return mg.Edges[i].Source < mg.Edges[j].Source
+		}
+		return mg.Edges[i].Target < mg.Edges[j].Target
+	})
+
+	return json.Marshal(mg)
+}
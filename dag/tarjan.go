@@ -0,0 +1,121 @@
+package dag
+
+// tarjanSCC computes the strongly connected components of g using
+// Tarjan's algorithm, traversing DownEdges as adjacency. It is
+// implemented iteratively, with an explicit stack of call frames in
+// place of recursion, so it doesn't blow out the goroutine stack on
+// large graphs.
+func tarjanSCC(g *Graph) [][]Vertex {
+	t := &tarjan{
+		g:       g,
+		index:   make(map[Vertex]int),
+		lowlink: make(map[Vertex]int),
+		onStack: make(map[Vertex]bool),
+	}
+
+	for _, v := range g.Vertices() {
+		if _, ok := t.index[v]; !ok {
+			t.strongConnect(v)
+		}
+	}
+
+	return t.result
+}
+
+// tarjan holds the state threaded through a run of Tarjan's algorithm:
+// the discovery index and lowlink of each vertex, which vertices are
+// currently on the stack, and the stack itself.
+type tarjan struct {
+	g *Graph
+
+	counter int
+	index   map[Vertex]int
+	lowlink map[Vertex]int
+	onStack map[Vertex]bool
+	stack   []Vertex
+
+	result [][]Vertex
+}
+
+// tarjanFrame is one level of the explicit call stack that replaces
+// strongConnect's recursion into each neighbor.
+type tarjanFrame struct {
+	v         Vertex
+	neighbors []Vertex
+	i         int
+}
+
+// strongConnect runs Tarjan's algorithm rooted at v.
+func (t *tarjan) strongConnect(v Vertex) {
+	frames := []*tarjanFrame{t.visit(v)}
+
+	for len(frames) > 0 {
+		f := frames[len(frames)-1]
+
+		if f.i < len(f.neighbors) {
+			w := f.neighbors[f.i]
+			f.i++
+
+			if _, ok := t.index[w]; !ok {
+				frames = append(frames, t.visit(w))
+				continue
+			}
+
+			if t.onStack[w] && t.index[w] < t.lowlink[f.v] {
+				t.lowlink[f.v] = t.index[w]
+			}
+
+			continue
+		}
+
+		// All of f.v's neighbors have been visited; pop it and
+		// propagate its lowlink up to whichever frame called into it.
+		frames = frames[:len(frames)-1]
+		if len(frames) > 0 {
+			parent := frames[len(frames)-1]
+			if t.lowlink[f.v] < t.lowlink[parent.v] {
+				t.lowlink[parent.v] = t.lowlink[f.v]
+			}
+		}
+
+		if t.lowlink[f.v] == t.index[f.v] {
+			t.result = append(t.result, t.popComponent(f.v))
+		}
+	}
+}
+
+// visit assigns v its index/lowlink, pushes it onto the Tarjan stack,
+// and returns the frame used to iterate its neighbors.
+func (t *tarjan) visit(v Vertex) *tarjanFrame {
+	t.index[v] = t.counter
+	t.lowlink[v] = t.counter
+	t.counter++
+
+	t.stack = append(t.stack, v)
+	t.onStack[v] = true
+
+	neighborsRaw := t.g.DownEdges(v).List()
+	neighbors := make([]Vertex, len(neighborsRaw))
+	for i, raw := range neighborsRaw {
+		neighbors[i] = raw.(Vertex)
+	}
+
+	return &tarjanFrame{v: v, neighbors: neighbors}
+}
+
+// popComponent pops the strongly connected component rooted at v off
+// the Tarjan stack.
+func (t *tarjan) popComponent(v Vertex) []Vertex {
+	var scc []Vertex
+	for {
+		n := len(t.stack)
+		w := t.stack[n-1]
+		t.stack = t.stack[:n-1]
+		t.onStack[w] = false
+
+		scc = append(scc, w)
+		if w == v {
+			return scc
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package dag
+
+import "testing"
+
+func TestAcyclicGraph_Cycles(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Add(4)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(2, 1))
+	g.Connect(BasicEdge(3, 4))
+	g.Connect(BasicEdge(4, 4))
+
+	cycles := g.Cycles()
+	if len(cycles) != 2 {
+		t.Fatalf("expected 2 cycles (the 1<->2 loop and the 4 self-loop), got %d: %#v", len(cycles), cycles)
+	}
+
+	var sawPair, sawSelf bool
+	for _, cycle := range cycles {
+		switch len(cycle) {
+		case 1:
+			if cycle[0] == 4 {
+				sawSelf = true
+			}
+		case 2:
+			sawPair = true
+		}
+	}
+
+	if !sawPair || !sawSelf {
+		t.Fatalf("expected both a 2-vertex cycle and a self-loop cycle, got %#v", cycles)
+	}
+}
+
+func TestAcyclicGraph_TransitiveReduction_cycle(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(2, 1))
+
+	if err := g.TransitiveReduction(); err == nil {
+		t.Fatal("expected an error for a graph with a cycle")
+	}
+}
@@ -1,18 +1,38 @@
 package dag
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"sync"
 	"time"
-
-	"github.com/hashicorp/go-multierror"
 )
 
-// walker performs a graph walk
-type walker struct {
+// Walker performs a dynamic walk of a graph: unlike Walk, a Walker's
+// vertices and edges can be changed with Update while the walk is still
+// in flight. This is used, for example, by callers that discover new
+// vertices (such as resources) as a result of executing other vertices.
+//
+// Create a Walker with AcyclicGraph.Walker, call Update one or more times
+// to populate (and mutate) it, and call Wait to block until the walk is
+// done and collect any errors.
+type Walker struct {
 	Callback WalkFunc
 
+	// Context, if non-nil, is checked before each vertex's callback
+	// starts executing. Once it's done, in-flight callbacks are left to
+	// run to completion but every vertex that hasn't started yet is
+	// marked as skipped and treated like an errored dependency for
+	// downstream propagation. Set by WalkCtx.
+	Context context.Context
+
+	// MaxParallel, if greater than zero, limits how many vertex
+	// callbacks may execute concurrently. Set by WalkCtx.
+	MaxParallel int
+
+	// diagCallback, if set, takes priority over Callback and lets a
+	// vertex return warnings alongside errors. It is set by WalkDiag.
+	diagCallback DiagWalkFunc
+
 	vertices  *Set
 	edges     *Set
 	vertexMap map[Vertex]*walkerVertex
@@ -20,10 +40,109 @@ type walker struct {
 	wait       sync.WaitGroup
 	changeLock sync.Mutex
 
-	errMap  map[Vertex]error
+	semOnce sync.Once
+	sem     chan struct{}
+
+	diagMap map[Vertex]Diagnostics
+	failed  map[Vertex]bool
 	errLock sync.Mutex
 }
 
+// ctxDone returns the Done channel of w.Context, or nil if no context
+// was set. A nil channel blocks forever in a select, which is exactly
+// the behavior we want when there's nothing to cancel on.
+func (w *Walker) ctxDone() <-chan struct{} {
+	if w.Context == nil {
+		return nil
+	}
+
+	return w.Context.Done()
+}
+
+// acquire blocks until a slot is free under MaxParallel (if set), or
+// until cancelCh or w.ctxDone() fires, whichever happens first. ok
+// reports whether a slot was acquired; cancelled reports whether a slot
+// was missed because the walk's context is done, as opposed to the
+// vertex simply having been removed via cancelCh.
+func (w *Walker) acquire(cancelCh <-chan struct{}) (ok, cancelled bool) {
+	w.semOnce.Do(func() {
+		if w.MaxParallel > 0 {
+			w.sem = make(chan struct{}, w.MaxParallel)
+		}
+	})
+
+	if w.sem == nil {
+		// No MaxParallel limit to wait on, but we still need to honor
+		// cancellation rather than unconditionally reporting success.
+		select {
+		case <-w.ctxDone():
+			return false, true
+		default:
+			return true, false
+		}
+	}
+
+	select {
+	case w.sem <- struct{}{}:
+		return true, false
+	case <-cancelCh:
+		return false, false
+	case <-w.ctxDone():
+		return false, true
+	}
+}
+
+// release frees the slot acquired by a successful call to acquire.
+func (w *Walker) release() {
+	if w.sem != nil {
+		<-w.sem
+	}
+}
+
+// markFailed records v as failed (whether due to an error or a skip
+// caused by a failed dependency) so that its downstream vertices are
+// skipped in turn. It does not add a diagnostic of its own: either the
+// callback already recorded one, or v is being skipped silently because
+// of something upstream that already has.
+func (w *Walker) markFailed(v Vertex) {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	if w.failed == nil {
+		w.failed = make(map[Vertex]bool)
+	}
+	w.failed[v] = true
+}
+
+// markCancelled records v as failed, like markFailed, but also records
+// a diagnostic explaining that v never ran because the walk's context
+// was done. Without this, a walk aborted purely by context cancellation
+// (with no vertex callback itself erroring) would report no error at
+// all from Wait/WalkCtx.
+func (w *Walker) markCancelled(v Vertex) {
+	detail := "walk canceled before this vertex started"
+	if w.Context != nil && w.Context.Err() != nil {
+		detail = w.Context.Err().Error()
+	}
+
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	if w.diagMap == nil {
+		w.diagMap = make(map[Vertex]Diagnostics)
+	}
+	w.diagMap[v] = Diagnostics{&diagnostic{
+		severity: Error,
+		summary:  VertexName(v),
+		detail:   detail,
+	}}
+
+	if w.failed == nil {
+		w.failed = make(map[Vertex]bool)
+	}
+	w.failed[v] = true
+}
+
 type walkerVertex struct {
 	sync.Mutex
 
@@ -38,30 +157,37 @@ type walkerVertex struct {
 
 // Wait waits for the completion of the walk and returns any errors (
 // in the form of a multierror) that occurred. Update should be called
-// to populate the walk with vertices and edges.
-func (w *walker) Wait() error {
+// to populate the walk with vertices and edges. Callers that also need
+// the warnings produced by a DiagWalkFunc should call Diags instead.
+func (w *Walker) Wait() error {
 	// Wait for completion
 	w.wait.Wait()
 
-	// Grab the error lock
+	return w.Diags().Err()
+}
+
+// Diags waits for the completion of the walk and returns every
+// diagnostic (warnings and errors alike) accumulated across all visited
+// vertices.
+func (w *Walker) Diags() Diagnostics {
+	w.wait.Wait()
+
 	w.errLock.Lock()
 	defer w.errLock.Unlock()
 
-	// Build the error
-	var result error
-	for v, err := range w.errMap {
-		result = multierror.Append(result, fmt.Errorf(
-			"%s: %s", VertexName(v), err))
+	var diags Diagnostics
+	for _, vDiags := range w.diagMap {
+		diags = diags.Append(vDiags...)
 	}
 
-	return result
+	return diags
 }
 
 // Update updates the currently executing walk with the given vertices
 // and edges. It does not block until completion.
 //
 // Update can be called in parallel to Walk.
-func (w *walker) Update(v, e *Set) {
+func (w *Walker) Update(v, e *Set) {
 	// Grab the change lock so no more updates happen but also so that
 	// no new vertices are executed during this time since we may be
 	// removing them.
@@ -185,11 +311,16 @@ func (w *walker) Update(v, e *Set) {
 		v := raw.(Vertex)
 		go w.walkVertex(v, w.vertexMap[v])
 	}
+
+	// Remember the vertices and edges we just processed so the next
+	// call to Update only sees what has changed since this call.
+	w.vertices = v
+	w.edges = e
 }
 
 // walkVertex walks a single vertex, waiting for any dependencies before
 // executing the callback.
-func (w *walker) walkVertex(v Vertex, info *walkerVertex) {
+func (w *Walker) walkVertex(v Vertex, info *walkerVertex) {
 	// When we're done executing, lower the waitgroup count
 	defer w.wait.Done()
 
@@ -204,6 +335,12 @@ func (w *walker) walkVertex(v Vertex, info *walkerVertex) {
 			// Cancel
 			return
 
+		case <-w.ctxDone():
+			// The walk was canceled before we got a chance to start;
+			// propagate that downstream just like a failed dependency.
+			w.markCancelled(v)
+			return
+
 		case <-depsCh:
 			// Deps complete!
 			depsCh = nil
@@ -225,19 +362,92 @@ func (w *walker) walkVertex(v Vertex, info *walkerVertex) {
 		}
 	}
 
-	// Call our callback
-	if err := w.Callback(v); err != nil {
-		w.errLock.Lock()
-		defer w.errLock.Unlock()
+	// The select above treats a canceled context and a satisfied depsCh
+	// as equally eligible cases, so it may have picked depsCh even
+	// though the context was already done (this can happen, for
+	// example, for a root vertex with no deps to wait on, since its
+	// DepsCh is closed immediately by Update). Give cancellation
+	// priority with one last non-blocking check before we commit to
+	// running.
+	select {
+	case <-w.ctxDone():
+		w.markCancelled(v)
+		return
+	default:
+	}
+
+	// If any of our dependencies failed (either by erroring or by being
+	// skipped themselves), we skip our own callback and propagate the
+	// failure downstream rather than executing on partial results.
+	//
+	// info.deps is mutated by Update (under changeLock) whenever an
+	// edge is added, so we copy it under that same lock before
+	// inspecting it rather than racing with that write.
+	w.changeLock.Lock()
+	deps := make([]Vertex, 0, len(info.deps))
+	for dep := range info.deps {
+		deps = append(deps, dep)
+	}
+	w.changeLock.Unlock()
+
+	w.errLock.Lock()
+	skip := false
+	for _, dep := range deps {
+		if w.failed[dep] {
+			skip = true
+			break
+		}
+	}
+	w.errLock.Unlock()
+
+	if skip {
+		w.markFailed(v)
+		return
+	}
 
-		if w.errMap == nil {
-			w.errMap = make(map[Vertex]error)
+	// Wait for a free slot under MaxParallel before executing. If the
+	// vertex was removed while we waited, we just exit quietly like the
+	// CancelCh case above. If instead the walk's context is done, we
+	// never started, so we're marked failed like a canceled vertex.
+	ok, cancelled := w.acquire(info.CancelCh)
+	if !ok {
+		if cancelled {
+			w.markCancelled(v)
 		}
-		w.errMap[v] = err
+		return
+	}
+	defer w.release()
+
+	// Call our callback, preferring the diagnostics-returning form if
+	// one was given to us.
+	var diags Diagnostics
+	switch {
+	case w.diagCallback != nil:
+		diags = w.diagCallback(v)
+	case w.Callback != nil:
+		diags = diagnosticsForError(v, w.Callback(v))
+	}
+
+	if len(diags) == 0 {
+		return
+	}
+
+	w.errLock.Lock()
+	if w.diagMap == nil {
+		w.diagMap = make(map[Vertex]Diagnostics)
+	}
+	w.diagMap[v] = diags
+	w.errLock.Unlock()
+
+	// Only error-severity diagnostics cause downstream vertices to be
+	// skipped; a vertex that only produced warnings still counts as
+	// successfully visited.
+	if diags.HasErrors() {
+		w.markFailed(v)
 	}
 }
 
-func (w *walker) waitDeps(
+func (w *Walker) waitDeps(
 	v Vertex,
 	deps map[Vertex]<-chan struct{},
 	doneCh chan<- struct{},
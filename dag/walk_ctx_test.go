@@ -0,0 +1,66 @@
+package dag
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcyclicGraph_WalkCtx_cancel(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var executed sync.Map
+	err := g.WalkCtx(ctx, WalkOpts{}, func(v Vertex) error {
+		executed.Store(v, true)
+		if v == 1 {
+			cancel()
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error once the context was cancelled")
+	}
+
+	if _, ok := executed.Load(2); ok {
+		t.Fatal("vertex 2 should have been skipped after cancellation, not executed")
+	}
+}
+
+func TestAcyclicGraph_WalkCtx_maxParallel(t *testing.T) {
+	var g AcyclicGraph
+	for i := 1; i <= 5; i++ {
+		g.Add(i)
+	}
+
+	var concurrent int32
+	var maxSeen int32
+	err := g.WalkCtx(context.Background(), WalkOpts{MaxParallel: 2}, func(v Vertex) error {
+		cur := atomic.AddInt32(&concurrent, 1)
+		defer atomic.AddInt32(&concurrent, -1)
+
+		for {
+			old := atomic.LoadInt32(&maxSeen)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxSeen, old, cur) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if maxSeen > 2 {
+		t.Fatalf("expected at most 2 concurrent callbacks, saw %d", maxSeen)
+	}
+}
@@ -0,0 +1,176 @@
+package dag
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalker_newVertex(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Connect(BasicEdge(1, 2))
+
+	var mu sync.Mutex
+	var visited []interface{}
+	cb := func(v Vertex) error {
+		mu.Lock()
+		defer mu.Unlock()
+		visited = append(visited, v)
+		return nil
+	}
+
+	w := g.Walker(cb)
+
+	vertices := new(Set)
+	vertices.Add(1)
+	edges := new(Set)
+	w.Update(vertices, edges)
+
+	// Give the walker a moment to visit vertex 1, then add vertex 2
+	// and the edge to it while the walk is still running.
+	time.Sleep(50 * time.Millisecond)
+
+	vertices = vertices.Copy()
+	vertices.Add(2)
+	edges = edges.Copy()
+	edges.Add(BasicEdge(1, 2))
+	w.Update(vertices, edges)
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(visited) != 2 {
+		t.Fatalf("expected 2 vertices visited, got %d: %#v", len(visited), visited)
+	}
+}
+
+func TestWalker_newEdge(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+
+	var mu sync.Mutex
+	var order []interface{}
+	cb := func(v Vertex) error {
+		mu.Lock()
+		order = append(order, v)
+		mu.Unlock()
+		return nil
+	}
+
+	w := g.Walker(cb)
+
+	vertices := new(Set)
+	vertices.Add(1)
+	vertices.Add(2)
+	edges := new(Set)
+	w.Update(vertices, edges)
+
+	// Now add an edge that forces 2 to wait on 1.
+	edges = edges.Copy()
+	edges.Add(BasicEdge(1, 2))
+	w.Update(vertices, edges)
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("expected [1 2], got %#v", order)
+	}
+}
+
+func TestWalker_removeVertex(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+
+	block := make(chan struct{})
+	var mu sync.Mutex
+	var visited []interface{}
+	var vertex2Ran bool
+	cb := func(v Vertex) error {
+		if v == 1 {
+			<-block
+		}
+
+		if v == 2 {
+			vertex2Ran = true
+		}
+
+		mu.Lock()
+		visited = append(visited, v)
+		mu.Unlock()
+		return nil
+	}
+
+	w := g.Walker(cb)
+
+	// Make vertex 2 depend on vertex 1, which blocks on block, so vertex
+	// 2 is guaranteed to still be waiting on its deps (not executing or
+	// finished) for as long as block stays closed.
+	vertices := new(Set)
+	vertices.Add(1)
+	vertices.Add(2)
+	edges := new(Set)
+	edges.Add(BasicEdge(1, 2))
+	w.Update(vertices, edges)
+
+	// Remove vertex 2 while it is still waiting on vertex 1; this must
+	// take effect before vertex 2 ever runs.
+	vertices = new(Set)
+	vertices.Add(1)
+	w.Update(vertices, edges)
+
+	close(block)
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if vertex2Ran {
+		t.Fatal("expected vertex 2 to be removed before it ran, but its callback executed")
+	}
+}
+
+func TestWalker_errorPropagation(t *testing.T) {
+	var g AcyclicGraph
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(2, 3))
+
+	var mu sync.Mutex
+	executed := make(map[interface{}]bool)
+	cb := func(v Vertex) error {
+		mu.Lock()
+		executed[v] = true
+		mu.Unlock()
+
+		if v == 1 {
+			return fmt.Errorf("vertex 1 failed")
+		}
+
+		return nil
+	}
+
+	if err := g.Walk(cb); err == nil {
+		t.Fatal("expected error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if executed[2] || executed[3] {
+		t.Fatalf("downstream vertices of a failed dependency should be skipped: %#v", executed)
+	}
+	if !executed[1] {
+		t.Fatal("expected vertex 1 to execute")
+	}
+}